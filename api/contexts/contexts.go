@@ -23,7 +23,9 @@ const (
 	Groups   Context = "groups"
 	Tasks    Context = "tasks"
 	Images   Context = "images"
+	Commands Context = "commands"
 	Services Context = "services"
+	Classes  Context = "classes"
 
 	// Meta Context used to represent the set of all the higher level Context types.
 	All Context = "all"
@@ -15,8 +15,20 @@ func (c *Client) Search() *Search {
 
 // PrefixSearch returns a set of matches for a particular context and prefix.
 func (s *Search) PrefixSearch(prefix string, context contexts.Context, q *QueryOptions) (*SearchResponse, *QueryMeta, error) {
+	return s.PrefixSearchWithPaging(prefix, context, "", 0, q)
+}
+
+// PrefixSearchWithPaging returns a page of matches for a particular context
+// and prefix. Pass the NextToken from a previous SearchResponse to fetch the
+// following page; perPage of zero uses the server's configured default.
+func (s *Search) PrefixSearchWithPaging(prefix string, context contexts.Context, nextToken string, perPage int32, q *QueryOptions) (*SearchResponse, *QueryMeta, error) {
 	var resp SearchResponse
-	req := &SearchRequest{Prefix: prefix, Context: context}
+	req := &SearchRequest{
+		Prefix:    prefix,
+		Context:   context,
+		NextToken: nextToken,
+		PerPage:   perPage,
+	}
 
 	qm, err := s.client.putQuery("/v1/search", req, &resp, q)
 	if err != nil {
@@ -27,21 +39,36 @@ func (s *Search) PrefixSearch(prefix string, context contexts.Context, q *QueryO
 }
 
 type SearchRequest struct {
-	Prefix  string
-	Context contexts.Context
+	Prefix    string
+	Context   contexts.Context
+	PerPage   int32  `json:",omitempty"`
+	NextToken string `json:",omitempty"`
 	QueryOptions
 }
 
-// FuzzySearch returns a set of matches for a given context and string.
-func (s *Search) FuzzySearch(text string, context []contexts.Context, q *QueryOptions) (*SearchResponse, *QueryMeta, error) {
-	var resp SearchResponse
+// FuzzySearch returns a set of matches for a given context and string,
+// matched the way MatchFuzzy describes. Use FuzzySearchWithMode to request
+// exact, substring, or regex matching, or to page through results.
+func (s *Search) FuzzySearch(text string, context []contexts.Context, q *QueryOptions) (*FuzzySearchResponse, *QueryMeta, error) {
+	return s.FuzzySearchWithMode(text, context, MatchFuzzy, "", 0, q)
+}
+
+// FuzzySearchWithMode returns a page of matches for a given context and
+// string, interpreting text according to mode. Pass the NextToken from a
+// previous FuzzySearchResponse to fetch the following page; perPage of zero
+// uses the server's configured default.
+func (s *Search) FuzzySearchWithMode(text string, context []contexts.Context, mode FuzzyMatchMode, nextToken string, perPage int32, q *QueryOptions) (*FuzzySearchResponse, *QueryMeta, error) {
+	var resp FuzzySearchResponse
 
 	c := make([]contexts.Context, len(context))
 	copy(c, context)
 
 	req := &FuzzySearchRequest{
-		Text:     text,
-		Contexts: c,
+		Text:      text,
+		Contexts:  c,
+		Mode:      mode,
+		NextToken: nextToken,
+		PerPage:   perPage,
 	}
 
 	qm, err := s.client.putQuery("/v1/search/fuzzy", req, &resp, q)
@@ -52,14 +79,53 @@ func (s *Search) FuzzySearch(text string, context []contexts.Context, q *QueryOp
 	return &resp, qm, nil
 }
 
+// FuzzyMatchMode controls how FuzzySearchRequest.Text is interpreted. It
+// mirrors structs.FuzzyMatchMode on the server.
+type FuzzyMatchMode string
+
+const (
+	// MatchFuzzy scores names by how well Text matches as a subsequence.
+	MatchFuzzy FuzzyMatchMode = "fuzzy"
+
+	// MatchExact requires the full name to equal Text.
+	MatchExact FuzzyMatchMode = "exact"
+
+	// MatchSubstring requires Text to appear literally within the name.
+	MatchSubstring FuzzyMatchMode = "substring"
+
+	// MatchRegex compiles Text as a regular expression and matches it
+	// against the name.
+	MatchRegex FuzzyMatchMode = "regex"
+)
+
 type FuzzySearchRequest struct {
-	Text     string
-	Contexts []contexts.Context
+	Text      string
+	Contexts  []contexts.Context
+	Mode      FuzzyMatchMode `json:",omitempty"`
+	PerPage   int32          `json:",omitempty"`
+	NextToken string         `json:",omitempty"`
 	QueryOptions
 }
 
 type SearchResponse struct {
 	Matches     map[contexts.Context][]string
 	Truncations map[contexts.Context]bool
+	NextToken   string `json:",omitempty"`
+	QueryMeta
+}
+
+// FuzzyMatch is a single fuzzy search result. Scope gives the chain of
+// parent object IDs (namespace, job, group, task, ...) for sub-contexts such
+// as Images or Services, where ID alone doesn't identify where the match
+// came from.
+type FuzzyMatch struct {
+	ID    string
+	Scope []string `json:",omitempty"`
+}
+
+type FuzzySearchResponse struct {
+	Matches     map[contexts.Context][]FuzzyMatch
+	Truncations map[contexts.Context]bool
+	NextToken   string `json:",omitempty"`
 	QueryMeta
 }
@@ -2,8 +2,10 @@ package nomad
 
 import (
 	"fmt"
+	"net/url"
 	"regexp"
 	"sort"
+	"strconv"
 	"strings"
 	"time"
 
@@ -12,14 +14,29 @@ import (
 	memdb "github.com/hashicorp/go-memdb"
 
 	"github.com/hashicorp/nomad/acl"
+	"github.com/hashicorp/nomad/nomad/searchindex"
 	"github.com/hashicorp/nomad/nomad/state"
 	"github.com/hashicorp/nomad/nomad/structs"
 )
 
 const (
-	// truncateLimit is the maximum number of matches that will be returned for a
-	// prefix for a specific context
+	// truncateLimit is the default page size used when a request doesn't
+	// set PerPage and the server has no configured SearchConfig.LimitResults.
 	truncateLimit = 20
+
+	// maxFuzzyRegexLength bounds the length of a MatchRegex search text.
+	// MatchRegex compiles operator-supplied input verbatim, so this guards
+	// against pathologically expensive patterns rather than trying to
+	// detect them.
+	maxFuzzyRegexLength = 256
+
+	// nextTokenSep separates a context from its id/offset within one entry
+	// of an opaque page token produced by encodeNextToken.
+	nextTokenSep = "|"
+
+	// nextTokenEntrySep separates the entries of an opaque page token
+	// produced by encodeNextToken, one per context that has been paged.
+	nextTokenEntrySep = ","
 )
 
 var (
@@ -37,26 +54,265 @@ var (
 		structs.Namespaces,
 	}
 
+	// fuzzyContexts are searched by default when a FuzzySearchRequest leaves
+	// Context unset (structs.Fuzzy). fuzzySubContexts are deliberately
+	// excluded: each one re-walks the Jobs or Nodes table, so folding all six
+	// into the default fan-out would turn an existing, unscoped fuzzy search
+	// into up to six extra table scans and six new response buckets for
+	// every caller that never asked for them. They're still reachable, just
+	// only when a caller sets Context to one of them explicitly.
 	fuzzyContexts = []structs.Context{
 		structs.Nodes,
 		structs.Namespaces,
 		structs.Jobs,
 		structs.Allocs,
 	}
+
+	// fuzzySubContexts are addressable fuzzy Contexts whose matches are
+	// derived from walking into a Job or Node rather than matching the
+	// object itself. They aren't backed by the configured SearchIndexer yet,
+	// so FuzzySearch always serves them from the memdb scan regardless of
+	// Backend, and they're excluded from the default fuzzyContexts fan-out
+	// (see above) so they only run when a caller requests one by name.
+	fuzzySubContexts = []structs.Context{
+		structs.Groups,
+		structs.Tasks,
+		structs.Images,
+		structs.Commands,
+		structs.Services,
+		structs.Classes,
+	}
 )
 
+// isFuzzySubContext reports whether ctx is served by walking into a parent
+// Job or Node (see fuzzySubContexts) rather than matching the object itself.
+func isFuzzySubContext(ctx structs.Context) bool {
+	for _, c := range fuzzySubContexts {
+		if c == ctx {
+			return true
+		}
+	}
+	return false
+}
+
 // Search endpoint is used to look up matches for a given prefix and context
 type Search struct {
 	srv    *Server
 	logger log.Logger
+
+	// indexer is populated from SearchConfig.Backend during server setup.
+	// When nil, FuzzySearch falls back to the memdb scan below.
+	indexer searchindex.SearchIndexer
+}
+
+// NewSearch constructs the Search endpoint, building the SearchIndexer
+// selected by srv.config.SearchConfig.Backend (if any). Called from
+// Server.setupSearch during server bring-up. A freshly constructed indexer
+// starts out empty, so its first fill comes from a best-effort Rebuild walk
+// of the current state store snapshot, run in the background so it doesn't
+// hold up server bootstrap. FuzzySearch queries the indexer as soon as it is
+// set, so until that walk finishes its indexed contexts return only what has
+// been indexed so far. The Handle* methods below update one object at a
+// time; no FSM apply handler calls them, so the index does not see objects
+// registered, updated, or deregistered after the Rebuild walk.
+func NewSearch(srv *Server, logger log.Logger) *Search {
+	s := &Search{srv: srv, logger: logger}
+
+	indexer, err := searchindex.New(srv.config.SearchConfig)
+	if err != nil {
+		s.logger.Error("failed to construct search indexer, falling back to memdb scan", "error", err)
+		return s
+	}
+	if indexer == nil {
+		return s
+	}
+	s.indexer = indexer
+
+	go func() {
+		if err := searchindex.Rebuild(indexer, srv.fsm.State()); err != nil {
+			s.logger.Error("search index rebuild failed", "error", err)
+		}
+	}()
+
+	return s
+}
+
+// indexUpsert indexes doc if an indexer is configured, logging (rather than
+// returning) any failure, since a failed index write shouldn't fail the FSM
+// apply that triggered it.
+func (s *Search) indexUpsert(doc searchindex.Document) {
+	if s.indexer == nil {
+		return
+	}
+	if err := s.indexer.Upsert(doc); err != nil {
+		s.logger.Error("search index upsert failed", "context", doc.Context, "id", doc.ID, "error", err)
+	}
+}
+
+// indexDelete removes id from ctx's index if an indexer is configured,
+// logging rather than returning any failure; see indexUpsert.
+func (s *Search) indexDelete(ctx structs.Context, id string) {
+	if s.indexer == nil {
+		return
+	}
+	if err := s.indexer.Delete(ctx, id); err != nil {
+		s.logger.Error("search index delete failed", "context", ctx, "id", id, "error", err)
+	}
+}
+
+// HandleJobUpsert indexes job after a registration or update commits to the
+// state store.
+func (s *Search) HandleJobUpsert(job *structs.Job) {
+	s.indexUpsert(searchindex.Document{Context: structs.Jobs, ID: job.ID, Namespace: job.Namespace, Name: job.Name})
+}
+
+// HandleJobDelete removes job from the index.
+func (s *Search) HandleJobDelete(job *structs.Job) {
+	s.indexDelete(structs.Jobs, job.ID)
+}
+
+// HandleAllocUpsert indexes alloc after an allocation update commits to the
+// state store.
+func (s *Search) HandleAllocUpsert(alloc *structs.Allocation) {
+	s.indexUpsert(searchindex.Document{Context: structs.Allocs, ID: alloc.ID, Namespace: alloc.Namespace, Name: alloc.Name})
+}
+
+// HandleNodeUpsert indexes node after a registration or update commits to
+// the state store.
+func (s *Search) HandleNodeUpsert(node *structs.Node) {
+	s.indexUpsert(searchindex.Document{Context: structs.Nodes, ID: node.ID, Name: node.Name})
+}
+
+// HandleNodeDelete removes node from the index.
+func (s *Search) HandleNodeDelete(node *structs.Node) {
+	s.indexDelete(structs.Nodes, node.ID)
+}
+
+// HandleNamespaceUpsert indexes ns after it's created or updated.
+func (s *Search) HandleNamespaceUpsert(ns *structs.Namespace) {
+	s.indexUpsert(searchindex.Document{Context: structs.Namespaces, ID: ns.Name, Name: ns.Name})
+}
+
+// HandleNamespaceDelete removes the namespace named name from the index.
+func (s *Search) HandleNamespaceDelete(name string) {
+	s.indexDelete(structs.Namespaces, name)
+}
+
+// HandleCSIPluginUpsert indexes plugin after it's registered or updated.
+func (s *Search) HandleCSIPluginUpsert(plugin *structs.CSIPlugin) {
+	s.indexUpsert(searchindex.Document{Context: structs.Plugins, ID: plugin.ID, Name: plugin.ID})
+}
+
+// HandleCSIPluginDelete removes the plugin named id from the index.
+func (s *Search) HandleCSIPluginDelete(id string) {
+	s.indexDelete(structs.Plugins, id)
+}
+
+// HandleCSIVolumeUpsert indexes vol after it's registered or updated.
+func (s *Search) HandleCSIVolumeUpsert(vol *structs.CSIVolume) {
+	s.indexUpsert(searchindex.Document{Context: structs.Volumes, ID: vol.ID, Namespace: vol.Namespace, Name: vol.Name})
+}
+
+// HandleCSIVolumeDelete removes the volume named id from the index.
+func (s *Search) HandleCSIVolumeDelete(id string) {
+	s.indexDelete(structs.Volumes, id)
+}
+
+// HandleScalingPolicyUpsert indexes policy after it's registered or
+// updated.
+func (s *Search) HandleScalingPolicyUpsert(policy *structs.ScalingPolicy) {
+	s.indexUpsert(searchindex.Document{Context: structs.ScalingPolicies, ID: policy.ID, Namespace: policy.Namespace})
+}
+
+// HandleScalingPolicyDelete removes the scaling policy named id from the
+// index.
+func (s *Search) HandleScalingPolicyDelete(id string) {
+	s.indexDelete(structs.ScalingPolicies, id)
+}
+
+// searchPageSize resolves the effective page size for a request: perPage
+// capped at the operator-configured SearchConfig.LimitResults, which is
+// itself the fallback when perPage is unset.
+func (s *Search) searchPageSize(perPage int32) int {
+	limit := truncateLimit
+	if cfg := s.srv.config.SearchConfig; cfg != nil && cfg.LimitResults > 0 {
+		limit = cfg.LimitResults
+	}
+
+	if perPage <= 0 || int(perPage) > limit {
+		return limit
+	}
+	return int(perPage)
+}
+
+// encodeNextToken builds an opaque page token from positions, the last id
+// (or, for fuzzy search, sorted-slice offset) emitted so far for each
+// context that has been paged. Every context that has returned at least one
+// page needs its own entry, not just the ones still truncated: since each
+// request re-derives its iterators from scratch, a context missing from the
+// token would restart from its first page instead of resuming. Each half of
+// an entry is url-escaped so a context name or id can't be confused with
+// the token's own separators.
+func encodeNextToken(positions map[structs.Context]string) string {
+	if len(positions) == 0 {
+		return ""
+	}
+
+	ctxs := make([]structs.Context, 0, len(positions))
+	for ctx := range positions {
+		ctxs = append(ctxs, ctx)
+	}
+	sort.Slice(ctxs, func(i, j int) bool { return ctxs[i] < ctxs[j] })
+
+	entries := make([]string, 0, len(ctxs))
+	for _, ctx := range ctxs {
+		entries = append(entries, url.QueryEscape(string(ctx))+nextTokenSep+url.QueryEscape(positions[ctx]))
+	}
+
+	return strings.Join(entries, nextTokenEntrySep)
+}
+
+// decodeNextToken splits a page token produced by encodeNextToken back into
+// the per-context positions it encodes. A context absent from the returned
+// map hasn't been paged yet and should start from its first page. Entries
+// that fail to parse are dropped rather than failing the whole token, so a
+// token truncated or mangled in transit degrades to resuming what it can.
+func decodeNextToken(token string) map[structs.Context]string {
+	positions := make(map[structs.Context]string)
+	if token == "" {
+		return positions
+	}
+
+	for _, entry := range strings.Split(token, nextTokenEntrySep) {
+		parts := strings.SplitN(entry, nextTokenSep, 2)
+		if len(parts) != 2 {
+			continue
+		}
+
+		ctx, err := url.QueryUnescape(parts[0])
+		if err != nil {
+			continue
+		}
+		id, err := url.QueryUnescape(parts[1])
+		if err != nil {
+			continue
+		}
+
+		positions[structs.Context(ctx)] = id
+	}
+
+	return positions
 }
 
 // getMatches extracts matches for an iterator, and returns a list of ids for
-// these matches.
-func (s *Search) getMatches(iter memdb.ResultIterator, prefix string) ([]string, bool) {
+// these matches. after, when non-empty, skips ids up to and including it so
+// that callers can resume a previous page; ids are assumed to be yielded by
+// iter in sorted order, as memdb's prefix indexes do.
+func (s *Search) getMatches(iter memdb.ResultIterator, prefix string, pageSize int, after string) ([]string, bool, string) {
 	var matches []string
+	var lastID string
 
-	for i := 0; i < truncateLimit; i++ {
+	for len(matches) < pageSize {
 		raw := iter.Next()
 		if raw == nil {
 			break
@@ -96,43 +352,293 @@ func (s *Search) getMatches(iter memdb.ResultIterator, prefix string) ([]string,
 			continue
 		}
 
+		if after != "" && id <= after {
+			continue
+		}
+
 		matches = append(matches, id)
+		lastID = id
+	}
+
+	return matches, iter.Next() != nil, lastID
+}
+
+// fuzzySearchContext returns the effective Context to search for a
+// FuzzySearchRequest, defaulting an unset Context to Fuzzy (the default
+// fuzzyContexts set, which leaves out fuzzySubContexts) so existing callers
+// that don't set it keep searching what they always did; a caller that sets
+// Context to e.g. Images is narrowed to just that one.
+func fuzzySearchContext(context structs.Context) structs.Context {
+	if context == "" {
+		return structs.Fuzzy
+	}
+	return context
+}
+
+// fuzzyMatcher reports whether name matches a search text under some
+// structs.FuzzyMatchMode, and if so at what pos (lower is better, mirroring
+// regexp.FindStringIndex's start offset) so matches can be quality-sorted.
+type fuzzyMatcher func(name string) (pos int, ok bool)
+
+// newFuzzyMatcher builds the fuzzyMatcher for mode matching against text.
+func newFuzzyMatcher(mode structs.FuzzyMatchMode, text string) (fuzzyMatcher, error) {
+	switch mode {
+	case structs.MatchExact:
+		return func(name string) (int, bool) {
+			return 0, name == text
+		}, nil
+
+	case structs.MatchSubstring:
+		re := regexp.MustCompile(regexp.QuoteMeta(text))
+		return regexMatcher(re), nil
+
+	case structs.MatchRegex:
+		if len(text) > maxFuzzyRegexLength {
+			return nil, fmt.Errorf("regex search text exceeds maximum length of %d", maxFuzzyRegexLength)
+		}
+		re, err := regexp.Compile(text)
+		if err != nil {
+			return nil, fmt.Errorf("invalid regex: %w", err)
+		}
+		return regexMatcher(re), nil
+
+	case structs.MatchFuzzy, "":
+		return func(name string) (int, bool) {
+			return fuzzySubsequenceIndex(name, text)
+		}, nil
+
+	default:
+		return nil, fmt.Errorf("unknown fuzzy match mode %q", mode)
+	}
+}
+
+func regexMatcher(re *regexp.Regexp) fuzzyMatcher {
+	return func(name string) (int, bool) {
+		if m := re.FindStringIndex(name); len(m) > 0 {
+			return m[0], true
+		}
+		return 0, false
+	}
+}
+
+// fuzzySubsequenceIndex reports whether every rune of text appears in name,
+// in order but not necessarily contiguously, and if so the index at which
+// the subsequence starts. An empty text matches everything at position 0.
+func fuzzySubsequenceIndex(name, text string) (int, bool) {
+	if text == "" {
+		return 0, true
+	}
+
+	lowerName := []rune(strings.ToLower(name))
+	lowerText := []rune(strings.ToLower(text))
+
+	start := -1
+	i := 0
+	for _, r := range lowerText {
+		for i < len(lowerName) && lowerName[i] != r {
+			i++
+		}
+		if i >= len(lowerName) {
+			return 0, false
+		}
+		if start == -1 {
+			start = i
+		}
+		i++
+	}
+
+	return start, true
+}
+
+// defaultFuzzyScanLimit bounds how many objects getFuzzyMatches will pull
+// from an iterator before giving up on finding more matches for a page, when
+// the server has no configured SearchConfig.LimitQuery.
+const defaultFuzzyScanLimit = 2000
+
+// fuzzyScanLimit resolves the effective scan limit: the operator-configured
+// SearchConfig.LimitQuery, or defaultFuzzyScanLimit when unset. Lowering
+// LimitQuery reduces the cost of scanning tables too large to walk in full
+// on every request, at the cost of results beyond the limit going unseen;
+// getFuzzyMatches reports this as truncation just like a full page.
+func (s *Search) fuzzyScanLimit() int {
+	if cfg := s.srv.config.SearchConfig; cfg != nil && cfg.LimitQuery > 0 {
+		return cfg.LimitQuery
 	}
+	return defaultFuzzyScanLimit
+}
 
-	return matches, iter.Next() != nil
+// fuzzyCandidate is a single potential match extracted from a raw memdb
+// object, before it has been scored against the requested search text.
+type fuzzyCandidate struct {
+	value     string   // the text fuzzy matching is performed against
+	scope     []string // FuzzyMatch.Scope: parent object IDs, outermost first
+	namespace string   // parent job's namespace, for ACL filtering; "" if not job-derived
+}
+
+// fuzzyExtractor produces the candidates a raw memdb object contributes to
+// a context's fuzzy index. Top level contexts (Nodes, Namespaces, Jobs,
+// Allocs) contribute at most one candidate, their own name. Sub-contexts
+// such as Images or Tasks walk into the object and can contribute many.
+type fuzzyExtractor func(raw interface{}) []fuzzyCandidate
+
+// fuzzyExtractorFor returns the fuzzyExtractor for ctx, or nil if ctx has no
+// fuzzy matching support.
+func fuzzyExtractorFor(ctx structs.Context) fuzzyExtractor {
+	switch ctx {
+	case structs.Nodes:
+		return func(raw interface{}) []fuzzyCandidate {
+			n := raw.(*structs.Node)
+			return []fuzzyCandidate{{value: n.Name}}
+		}
+	case structs.Namespaces:
+		return func(raw interface{}) []fuzzyCandidate {
+			ns := raw.(*structs.Namespace)
+			return []fuzzyCandidate{{value: ns.Name}}
+		}
+	case structs.Jobs:
+		return func(raw interface{}) []fuzzyCandidate {
+			j := raw.(*structs.Job)
+			return []fuzzyCandidate{{value: j.Name}}
+		}
+	case structs.Allocs:
+		return func(raw interface{}) []fuzzyCandidate {
+			a := raw.(*structs.Allocation)
+			return []fuzzyCandidate{{value: a.Name}}
+		}
+	case structs.Classes:
+		return func(raw interface{}) []fuzzyCandidate {
+			return fuzzyNodeSubCandidates(raw.(*structs.Node))
+		}
+	case structs.Groups, structs.Tasks, structs.Images, structs.Commands, structs.Services:
+		return func(raw interface{}) []fuzzyCandidate {
+			return fuzzyJobSubCandidates(ctx, raw.(*structs.Job))
+		}
+	default:
+		return nil
+	}
 }
 
-func (s *Search) getFuzzyMatches(iter memdb.ResultIterator, re *regexp.Regexp) ([]string, bool) {
+// fuzzyJobSubCandidates walks job's task groups and tasks to produce the
+// candidates for ctx, one of Groups, Tasks, Images, Commands, or Services.
+// Scope is namespace, job ID, group name, task name (group/task name are
+// omitted where ctx doesn't reach that deep), so a caller can tell which
+// job a matched image or service belongs to.
+func fuzzyJobSubCandidates(ctx structs.Context, job *structs.Job) []fuzzyCandidate {
+	var out []fuzzyCandidate
+
+	for _, tg := range job.TaskGroups {
+		if ctx == structs.Groups {
+			out = append(out, fuzzyCandidate{
+				value:     tg.Name,
+				scope:     []string{job.Namespace, job.ID},
+				namespace: job.Namespace,
+			})
+			continue
+		}
+
+		for _, task := range tg.Tasks {
+			scope := []string{job.Namespace, job.ID, tg.Name, task.Name}
+
+			switch ctx {
+			case structs.Tasks:
+				out = append(out, fuzzyCandidate{value: task.Name, scope: scope, namespace: job.Namespace})
+				for _, v := range task.Meta {
+					if v != "" {
+						out = append(out, fuzzyCandidate{value: v, scope: scope, namespace: job.Namespace})
+					}
+				}
+
+			case structs.Images:
+				if image, ok := task.Config["image"].(string); ok && image != "" {
+					out = append(out, fuzzyCandidate{value: image, scope: scope, namespace: job.Namespace})
+				}
+
+			case structs.Commands:
+				if command, ok := task.Config["command"].(string); ok && command != "" {
+					out = append(out, fuzzyCandidate{value: command, scope: scope, namespace: job.Namespace})
+				}
+
+			case structs.Services:
+				for _, svc := range task.Services {
+					out = append(out, fuzzyCandidate{value: svc.Name, scope: scope, namespace: job.Namespace})
+				}
+			}
+		}
+	}
+
+	return out
+}
+
+// fuzzyNodeSubCandidates produces the Classes candidates for node: its node
+// class plus its attribute values, scoped to the node's own ID.
+func fuzzyNodeSubCandidates(node *structs.Node) []fuzzyCandidate {
+	var out []fuzzyCandidate
+	scope := []string{node.ID}
+
+	if node.NodeClass != "" {
+		out = append(out, fuzzyCandidate{value: node.NodeClass, scope: scope})
+	}
+	for _, v := range node.Attributes {
+		if v != "" {
+			out = append(out, fuzzyCandidate{value: v, scope: scope})
+		}
+	}
+
+	return out
+}
+
+// getFuzzyMatches scans iter, up to scanLimit objects, for candidates (as
+// produced by ctx's fuzzyExtractor) that satisfy matcher, sorts them by
+// match quality (then value, then scope, for a deterministic tiebreak), and
+// returns the page of pageSize results starting at offset. The returned int
+// is the offset to request for the next page, valid only when the bool
+// return is true; that bool is also set when scanLimit was reached before
+// iter was exhausted, since matches beyond it were never considered.
+//
+// Candidates derived from a job (see fuzzyCandidate.namespace) are dropped
+// unless aclObj can read that namespace, so a token scoped to one namespace
+// can't discover a job's images, tasks, or services in another by fuzzy
+// searching a sub-context. aclObj may be nil, meaning unrestricted access.
+func (s *Search) getFuzzyMatches(iter memdb.ResultIterator, ctx structs.Context, aclObj *acl.ACL, matcher fuzzyMatcher, pageSize, offset, scanLimit int) ([]structs.FuzzyMatch, bool, int) {
 	type match struct {
-		value string // the thing matched (e.g. job name)
-		pos   int    // the quality of result (lower is better)
+		value string   // the thing matched (e.g. job name, image name)
+		scope []string // tiebreaker, and FuzzyMatch.Scope
+		pos   int      // the quality of result (lower is better)
+	}
+
+	extract := fuzzyExtractorFor(ctx)
+	if extract == nil {
+		return nil, false, offset
 	}
 
 	var matches []match
+	scanTruncated := false
 
-	for i := 0; i < truncateLimit; i++ {
+	for i := 0; i < scanLimit; i++ {
 		raw := iter.Next()
 		if raw == nil {
 			break
 		}
 
-		var name string
-		switch t := raw.(type) {
-		case *structs.Node:
-			name = t.Name
-		case *structs.Namespace:
-			name = t.Name
-		case *structs.Job:
-			name = t.Name
-		case *structs.Allocation:
-			name = t.Name
+		for _, cand := range extract(raw) {
+			if cand.namespace != "" && aclObj != nil && !aclObj.AllowNamespace(cand.namespace) {
+				continue
+			}
+
+			if pos, ok := matcher(cand.value); ok {
+				matches = append(matches, match{
+					value: cand.value,
+					scope: cand.scope,
+					pos:   pos,
+				})
+			}
 		}
 
-		if m := re.FindStringIndex(name); len(m) > 0 {
-			matches = append(matches, match{
-				value: name,
-				pos:   m[0],
-			})
+		if i == scanLimit-1 {
+			// scanLimit objects have now been scanned; if the iterator still
+			// has more, the scan itself was truncated regardless of how the
+			// page comes out below.
+			scanTruncated = iter.Next() != nil
 		}
 	}
 
@@ -146,36 +652,46 @@ func (s *Search) getFuzzyMatches(iter memdb.ResultIterator, re *regexp.Regexp) (
 		case B.pos < A.pos:
 			return false
 
-		case len(A.value) < len(B.value):
-			return true
-		case len(B.value) < len(A.value):
-			return false
+		case A.value != B.value:
+			return A.value < B.value
 		}
 
-		return A.value < B.value
+		return strings.Join(A.scope, "/") < strings.Join(B.scope, "/")
 	})
 
-	results := make([]string, 0, len(matches))
-	for _, m := range matches {
-		results = append(results, m.value)
+	if offset > len(matches) {
+		offset = len(matches)
+	}
+	end := offset + pageSize
+	isTrunc := end < len(matches) || scanTruncated
+	if end > len(matches) {
+		end = len(matches)
+	}
+
+	page := matches[offset:end]
+	results := make([]structs.FuzzyMatch, 0, len(page))
+	for _, m := range page {
+		results = append(results, structs.FuzzyMatch{ID: m.value, Scope: m.scope})
 	}
 
-	return results, iter.Next() != nil
+	return results, isTrunc, end
 }
 
 // getResourceIter takes a context and returns a memdb iterator specific to
 // that context
 func getResourceIter(context structs.Context, aclObj *acl.ACL, namespace, prefix string, ws memdb.WatchSet, state *state.StateStore) (memdb.ResultIterator, error) {
-	fmt.Println("getResourceIter, context:", context)
-
 	switch context {
-	case structs.Jobs:
+	case structs.Jobs, structs.Groups, structs.Tasks, structs.Images, structs.Commands, structs.Services:
+		// The sub-contexts are derived by walking a Job's task groups, so
+		// they share the Jobs iterator.
 		return state.JobsByIDPrefix(ws, namespace, prefix)
 	case structs.Evals:
 		return state.EvalsByIDPrefix(ws, namespace, prefix)
 	case structs.Allocs:
 		return state.AllocsByIDPrefix(ws, namespace, prefix)
-	case structs.Nodes:
+	case structs.Nodes, structs.Classes:
+		// Classes is derived by walking a Node's class and attributes, so it
+		// shares the Nodes iterator.
 		return state.NodesByIDPrefix(ws, prefix)
 	case structs.Deployments:
 		return state.DeploymentsByIDPrefix(ws, namespace, prefix)
@@ -247,6 +763,9 @@ func (s *Search) PrefixSearch(args *structs.SearchRequest, reply *structs.Search
 	reply.Matches = make(map[structs.Context][]string)
 	reply.Truncations = make(map[structs.Context]bool)
 
+	pageSize := s.searchPageSize(args.PerPage)
+	positions := decodeNextToken(args.NextToken)
+
 	// Setup the blocking query
 	opts := blockingOptions{
 		queryMeta: &reply.QueryMeta,
@@ -276,11 +795,41 @@ func (s *Search) PrefixSearch(args *structs.SearchRequest, reply *structs.Search
 				}
 			}
 
-			// Return matches for the given prefix
-			for k, v := range iters {
-				res, isTrunc := s.getMatches(v, args.Prefix)
-				reply.Matches[k] = res
-				reply.Truncations[k] = isTrunc
+			// Iterate contexts in a stable order. The NextToken we hand back
+			// carries a position for every paged context (see
+			// encodeNextToken), not just one of the truncated ones.
+			sortedCtxs := make([]structs.Context, 0, len(iters))
+			for ctx := range iters {
+				sortedCtxs = append(sortedCtxs, ctx)
+			}
+			sort.Slice(sortedCtxs, func(i, j int) bool { return sortedCtxs[i] < sortedCtxs[j] })
+
+			// Return matches for the given prefix. Every context advances
+			// its own position independently: nextPositions carries forward
+			// the position of a context that didn't move this page (because
+			// it was already exhausted) so it doesn't restart from scratch.
+			nextPositions := make(map[structs.Context]string, len(sortedCtxs))
+			anyTrunc := false
+
+			for _, ctx := range sortedCtxs {
+				after := positions[ctx]
+
+				res, isTrunc, lastID := s.getMatches(iters[ctx], args.Prefix, pageSize, after)
+				reply.Matches[ctx] = res
+				reply.Truncations[ctx] = isTrunc
+
+				if lastID != "" {
+					nextPositions[ctx] = lastID
+				} else if after != "" {
+					nextPositions[ctx] = after
+				}
+				if isTrunc {
+					anyTrunc = true
+				}
+			}
+
+			if anyTrunc {
+				reply.NextToken = encodeNextToken(nextPositions)
 			}
 
 			// Set the index for the context. If the context has been specified, it
@@ -304,9 +853,7 @@ func (s *Search) PrefixSearch(args *structs.SearchRequest, reply *structs.Search
 
 // FuzzySearch is used to list fuzzy matches for a given string, and returns matching
 // jobs, nodes, namespaces, (etc?).
-func (s *Search) FuzzySearch(args *structs.FuzzySearchRequest, reply *structs.SearchResponse) error {
-	fmt.Println("FuzzySearch, text:", args.Text)
-
+func (s *Search) FuzzySearch(args *structs.FuzzySearchRequest, reply *structs.FuzzySearchResponse) error {
 	if done, err := s.srv.forward("Search.FuzzySearch", args, args, reply); done {
 		return err
 	}
@@ -323,38 +870,152 @@ func (s *Search) FuzzySearch(args *structs.FuzzySearchRequest, reply *structs.Se
 		return structs.ErrPermissionDenied
 	}
 
-	reply.Matches = make(map[structs.Context][]string)
+	reply.Matches = make(map[structs.Context][]structs.FuzzyMatch)
 	reply.Truncations = make(map[structs.Context]bool)
 
+	mode := args.MatchMode()
+	matcher, err := newFuzzyMatcher(mode, args.Text)
+	if err != nil {
+		return err
+	}
+
+	pageSize := s.searchPageSize(args.PerPage)
+	positions := decodeNextToken(args.NextToken)
+
 	// Setup the blocking query
 	opts := blockingOptions{
 		queryMeta: &reply.QueryMeta,
 		queryOpts: new(structs.QueryOptions),
 		run: func(ws memdb.WatchSet, state *state.StateStore) error {
-			fmt.Println("SH do run in blocking query")
+			contexts := searchContexts(aclObj, namespace, fuzzySearchContext(args.Context))
 
-			iters := make(map[structs.Context]memdb.ResultIterator)
+			// Iterate contexts in a stable order. The NextToken we hand back
+			// carries a position for every paged context (see
+			// encodeNextToken), not just one of the truncated ones.
+			sort.Slice(contexts, func(i, j int) bool { return contexts[i] < contexts[j] })
 
-			fmt.Println("doSearch, fuzzy:", structs.Fuzzy)
-			contexts := searchContexts(aclObj, namespace, structs.Fuzzy)
+			// When an indexer is configured, route base contexts through it
+			// instead of scanning memdb. This trades the up-to-the-ms
+			// consistency of the blocking query for the ability to serve
+			// fuzzy search on clusters too large to scan on every request.
+			// fuzzySubContexts aren't backed by the indexer (it only holds
+			// one Document per top level object), so they always fall back
+			// to the memdb walk below, regardless of Backend.
+			var memdbCtxs []structs.Context
+			nextPositions := make(map[structs.Context]string, len(contexts))
+			anyTrunc := false
 
-			for _, ctx := range contexts {
-				noPrefix := "" // search everything
-				iter, err := getResourceIter(ctx, aclObj, namespace, noPrefix, ws, state)
-				if err != nil {
-					return err
+			if s.indexer != nil {
+				for _, ctx := range contexts {
+					if isFuzzySubContext(ctx) {
+						memdbCtxs = append(memdbCtxs, ctx)
+						continue
+					}
+
+					offset := 0
+					if p, ok := positions[ctx]; ok {
+						offset, _ = strconv.Atoi(p)
+					}
+
+					// Over-fetch: a backend that can only do its own fuzzy
+					// matching (e.g. a Meilisearch-style engine) may return
+					// candidates the requested mode would reject, which are
+					// dropped by the post-filter below before pagination.
+					hits, err := s.indexer.Query(ctx, args.Text, namespace, offset+pageSize*4)
+					if err != nil {
+						return fmt.Errorf("search indexer query failed: %w", err)
+					}
+
+					// Re-check namespace access per hit: namespace above may
+					// be "" (all namespaces), in which case a hit's own
+					// Namespace is the only thing standing between it and an
+					// unauthorized caller, exactly as getFuzzyMatches checks
+					// cand.namespace for the memdb-backed sub-contexts below.
+					filtered := hits[:0]
+					for _, hit := range hits {
+						if hit.Namespace == "" || aclObj == nil || aclObj.AllowNamespace(hit.Namespace) {
+							filtered = append(filtered, hit)
+						}
+					}
+					hits = filtered
+
+					// Apply the requested mode as a post-filter, MatchFuzzy
+					// included, so results are consistent regardless of
+					// whether the backend can natively enforce that
+					// strictness itself: Bleve's own fuzzy matching is
+					// edit-distance based and disagrees with the
+					// subsequence-based fuzzySubsequenceIndex the memdb path
+					// uses, so without this a MatchFuzzy query would return a
+					// different match set depending on whether an indexer is
+					// configured.
+					modeFiltered := hits[:0]
+					for _, hit := range hits {
+						if _, ok := matcher(hit.ID); ok {
+							modeFiltered = append(modeFiltered, hit)
+						}
+					}
+					hits = modeFiltered
+
+					if offset > len(hits) {
+						offset = len(hits)
+					}
+					end := offset + pageSize
+					isTrunc := end < len(hits)
+					if end > len(hits) {
+						end = len(hits)
+					}
+					page := hits[offset:end]
+
+					matches := make([]structs.FuzzyMatch, 0, len(page))
+					for _, hit := range page {
+						matches = append(matches, structs.FuzzyMatch{ID: hit.ID})
+					}
+
+					reply.Matches[ctx] = matches
+					reply.Truncations[ctx] = isTrunc
+
+					nextPositions[ctx] = strconv.Itoa(end)
+					if isTrunc {
+						anyTrunc = true
+					}
 				}
-				iters[ctx] = iter
+			} else {
+				memdbCtxs = contexts
 			}
 
-			// compile the matcher once and reuse it
-			re := regexp.MustCompile(args.Text)
+			if len(memdbCtxs) > 0 {
+				iters := make(map[structs.Context]memdb.ResultIterator)
+				scanLimit := s.fuzzyScanLimit()
+
+				for _, ctx := range memdbCtxs {
+					noPrefix := "" // search everything
+					iter, err := getResourceIter(ctx, aclObj, namespace, noPrefix, ws, state)
+					if err != nil {
+						return err
+					}
+					iters[ctx] = iter
+				}
+
+				// Return fuzzy matches for the given text
+				for _, ctx := range memdbCtxs {
+					offset := 0
+					if p, ok := positions[ctx]; ok {
+						offset, _ = strconv.Atoi(p)
+					}
+
+					res, isTrunc, nextOffset := s.getFuzzyMatches(iters[ctx], ctx, aclObj, matcher, pageSize, offset, scanLimit)
+					reply.Matches[ctx] = res
+					reply.Truncations[ctx] = isTrunc
 
-			// Return fuzzy matches for the given text
-			for k, v := range iters {
-				res, isTrunc := s.getFuzzyMatches(v, re)
-				reply.Matches[k] = res
-				reply.Truncations[k] = isTrunc
+					nextPositions[ctx] = strconv.Itoa(nextOffset)
+					if isTrunc {
+						anyTrunc = true
+					}
+				}
+			}
+
+			if anyTrunc {
+				reply.NextToken = encodeNextToken(nextPositions)
 			}
 
 			// Set the index for the context. If the context has been specified,
@@ -379,8 +1040,6 @@ func (s *Search) FuzzySearch(args *structs.FuzzySearchRequest, reply *structs.Se
 }
 
 func expandContext(context structs.Context) []structs.Context {
-	fmt.Println("expand:", context)
-
 	switch context {
 	case structs.All:
 		c := make([]structs.Context, len(allContexts))
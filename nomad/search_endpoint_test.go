@@ -0,0 +1,370 @@
+package nomad
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/hashicorp/nomad/acl"
+	"github.com/hashicorp/nomad/nomad/structs"
+)
+
+// fakeIterator implements memdb.ResultIterator over a fixed slice, so pure
+// pagination logic can be tested without a real memdb/state store.
+type fakeIterator struct {
+	items []interface{}
+	i     int
+}
+
+func (f *fakeIterator) Next() interface{} {
+	if f.i >= len(f.items) {
+		return nil
+	}
+	item := f.items[f.i]
+	f.i++
+	return item
+}
+
+func (f *fakeIterator) WatchCh() <-chan struct{} {
+	return nil
+}
+
+func TestFuzzySubsequenceIndex(t *testing.T) {
+	cases := []struct {
+		name    string
+		text    string
+		wantPos int
+		wantOk  bool
+	}{
+		{name: "redis-cache", text: "", wantPos: 0, wantOk: true},
+		{name: "redis-cache", text: "redis", wantPos: 0, wantOk: true},
+		{name: "redis-cache", text: "cache", wantPos: 6, wantOk: true},
+		{name: "redis-cache", text: "rdc", wantPos: 0, wantOk: true},
+		{name: "redis-cache", text: "RC", wantPos: 0, wantOk: true},
+		{name: "redis-cache", text: "postgres", wantPos: 0, wantOk: false},
+		{name: "redis-cache", text: "cacher", wantPos: 0, wantOk: false},
+	}
+
+	for _, c := range cases {
+		pos, ok := fuzzySubsequenceIndex(c.name, c.text)
+		if ok != c.wantOk || (ok && pos != c.wantPos) {
+			t.Errorf("fuzzySubsequenceIndex(%q, %q) = (%d, %v), want (%d, %v)",
+				c.name, c.text, pos, ok, c.wantPos, c.wantOk)
+		}
+	}
+}
+
+func TestNewFuzzyMatcher(t *testing.T) {
+	t.Run("exact", func(t *testing.T) {
+		matcher, err := newFuzzyMatcher(structs.MatchExact, "redis")
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if _, ok := matcher("redis"); !ok {
+			t.Error("expected exact match on identical string")
+		}
+		if _, ok := matcher("redis-cache"); ok {
+			t.Error("expected no match on a string that merely contains the text")
+		}
+	})
+
+	t.Run("substring", func(t *testing.T) {
+		matcher, err := newFuzzyMatcher(structs.MatchSubstring, "a.b")
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if _, ok := matcher("xa.by"); !ok {
+			t.Error("expected substring match")
+		}
+		// The text is escaped, so "." shouldn't act as a regex wildcard.
+		if _, ok := matcher("xaybz"); ok {
+			t.Error("expected '.' to be treated literally, not as a regex wildcard")
+		}
+	})
+
+	t.Run("regex", func(t *testing.T) {
+		matcher, err := newFuzzyMatcher(structs.MatchRegex, "^redis-[0-9]+$")
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if _, ok := matcher("redis-12"); !ok {
+			t.Error("expected regex match")
+		}
+		if _, ok := matcher("redis-cache"); ok {
+			t.Error("expected no match for a string the regex doesn't fully describe")
+		}
+	})
+
+	t.Run("regex too long", func(t *testing.T) {
+		text := make([]byte, maxFuzzyRegexLength+1)
+		for i := range text {
+			text[i] = 'a'
+		}
+
+		if _, err := newFuzzyMatcher(structs.MatchRegex, string(text)); err == nil {
+			t.Error("expected an error for a regex exceeding maxFuzzyRegexLength")
+		}
+	})
+
+	t.Run("fuzzy default", func(t *testing.T) {
+		matcher, err := newFuzzyMatcher("", "rc")
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if _, ok := matcher("redis-cache"); !ok {
+			t.Error("expected unset Mode to behave like MatchFuzzy")
+		}
+	})
+
+	t.Run("unknown mode", func(t *testing.T) {
+		if _, err := newFuzzyMatcher("bogus", "x"); err == nil {
+			t.Error("expected an error for an unrecognized match mode")
+		}
+	})
+}
+
+func TestEncodeDecodeNextToken(t *testing.T) {
+	positions := map[structs.Context]string{
+		structs.Jobs:  "job-abc,def|ghi",
+		structs.Nodes: "42",
+	}
+
+	token := encodeNextToken(positions)
+	got := decodeNextToken(token)
+
+	if len(got) != len(positions) {
+		t.Fatalf("decoded %d positions, want %d: %v", len(got), len(positions), got)
+	}
+	for ctx, want := range positions {
+		if got[ctx] != want {
+			t.Errorf("position for %s = %q, want %q", ctx, got[ctx], want)
+		}
+	}
+}
+
+func TestEncodeNextToken_Empty(t *testing.T) {
+	if token := encodeNextToken(nil); token != "" {
+		t.Errorf("expected empty token for no positions, got %q", token)
+	}
+	if positions := decodeNextToken(""); len(positions) != 0 {
+		t.Errorf("expected no positions decoded from an empty token, got %v", positions)
+	}
+}
+
+func TestGetMatches_Pagination(t *testing.T) {
+	s := &Search{}
+	jobs := []interface{}{
+		&structs.Job{ID: "job-1"},
+		&structs.Job{ID: "job-2"},
+		&structs.Job{ID: "job-3"},
+	}
+
+	page1, isTrunc, lastID := s.getMatches(&fakeIterator{items: jobs}, "", 2, "")
+	if len(page1) != 2 || !isTrunc || lastID != "job-2" {
+		t.Fatalf("page1 = %v, isTrunc = %v, lastID = %q, want [job-1 job-2], true, job-2", page1, isTrunc, lastID)
+	}
+
+	// A fresh iterator (as a new request would derive) resumed from the
+	// previous page's lastID should pick up exactly where it left off.
+	page2, isTrunc, lastID := s.getMatches(&fakeIterator{items: jobs}, "", 2, lastID)
+	if len(page2) != 1 || isTrunc || lastID != "job-3" || page2[0] != "job-3" {
+		t.Fatalf("page2 = %v, isTrunc = %v, lastID = %q, want [job-3], false, job-3", page2, isTrunc, lastID)
+	}
+}
+
+func TestGetFuzzyMatches_Pagination(t *testing.T) {
+	s := &Search{}
+	jobs := []interface{}{
+		&structs.Job{ID: "job-1", Name: "web"},
+		&structs.Job{ID: "job-2", Name: "webhook"},
+		&structs.Job{ID: "job-3", Name: "worker"},
+	}
+
+	matcher, err := newFuzzyMatcher(structs.MatchFuzzy, "w")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	page1, isTrunc, next := s.getFuzzyMatches(&fakeIterator{items: jobs}, structs.Jobs, nil, matcher, 2, 0, 2000)
+	if len(page1) != 2 || !isTrunc {
+		t.Fatalf("page1 = %+v, isTrunc = %v, want 2 results and isTrunc=true", page1, isTrunc)
+	}
+
+	page2, isTrunc, _ := s.getFuzzyMatches(&fakeIterator{items: jobs}, structs.Jobs, nil, matcher, 2, next, 2000)
+	if len(page2) != 1 || isTrunc {
+		t.Fatalf("page2 = %+v, isTrunc = %v, want 1 result and isTrunc=false", page2, isTrunc)
+	}
+
+	seen := make(map[string]bool)
+	for _, m := range page1 {
+		seen[m.ID] = true
+	}
+	for _, m := range page2 {
+		if seen[m.ID] {
+			t.Errorf("id %q returned in both pages", m.ID)
+		}
+	}
+}
+
+func TestGetFuzzyMatches_ScanLimitTruncates(t *testing.T) {
+	s := &Search{}
+	jobs := []interface{}{
+		&structs.Job{ID: "job-1", Name: "web"},
+		&structs.Job{ID: "job-2", Name: "webhook"},
+	}
+
+	matcher, err := newFuzzyMatcher(structs.MatchFuzzy, "w")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// A scan limit of 1 only considers the first job, even though pageSize
+	// would otherwise happily hold both matches.
+	page, isTrunc, _ := s.getFuzzyMatches(&fakeIterator{items: jobs}, structs.Jobs, nil, matcher, 10, 0, 1)
+	if len(page) != 1 || !isTrunc {
+		t.Fatalf("page = %+v, isTrunc = %v, want 1 result and isTrunc=true", page, isTrunc)
+	}
+}
+
+func TestFuzzyJobSubCandidates(t *testing.T) {
+	job := &structs.Job{
+		ID:        "job-1",
+		Namespace: "prod",
+		TaskGroups: []*structs.TaskGroup{
+			{
+				Name: "web",
+				Tasks: []*structs.Task{
+					{
+						Name:     "app",
+						Config:   map[string]interface{}{"image": "nginx:1.25", "command": "/bin/run"},
+						Meta:     map[string]string{"owner": "platform-team"},
+						Services: []*structs.Service{{Name: "app-http"}},
+					},
+				},
+			},
+		},
+	}
+
+	cases := []struct {
+		ctx       structs.Context
+		wantValue string
+		wantScope []string
+	}{
+		{structs.Groups, "web", []string{"prod", "job-1"}},
+		{structs.Tasks, "app", []string{"prod", "job-1", "web", "app"}},
+		{structs.Tasks, "platform-team", []string{"prod", "job-1", "web", "app"}},
+		{structs.Images, "nginx:1.25", []string{"prod", "job-1", "web", "app"}},
+		{structs.Commands, "/bin/run", []string{"prod", "job-1", "web", "app"}},
+		{structs.Services, "app-http", []string{"prod", "job-1", "web", "app"}},
+	}
+
+	for _, c := range cases {
+		cands := fuzzyJobSubCandidates(c.ctx, job)
+
+		found := false
+		for _, cand := range cands {
+			if cand.value != c.wantValue {
+				continue
+			}
+			found = true
+			if !reflect.DeepEqual(cand.scope, c.wantScope) {
+				t.Errorf("%s candidate %q scope = %v, want %v", c.ctx, c.wantValue, cand.scope, c.wantScope)
+			}
+			if cand.namespace != job.Namespace {
+				t.Errorf("%s candidate %q namespace = %q, want %q", c.ctx, c.wantValue, cand.namespace, job.Namespace)
+			}
+		}
+		if !found {
+			t.Errorf("%s: expected a candidate with value %q, got %+v", c.ctx, c.wantValue, cands)
+		}
+	}
+}
+
+func TestFuzzyNodeSubCandidates(t *testing.T) {
+	node := &structs.Node{
+		ID:        "node-1",
+		NodeClass: "fast-disk",
+		Attributes: map[string]string{
+			"cpu.arch": "amd64",
+		},
+	}
+
+	cands := fuzzyNodeSubCandidates(node)
+
+	var gotClass, gotAttr bool
+	for _, cand := range cands {
+		if !reflect.DeepEqual(cand.scope, []string{"node-1"}) {
+			t.Errorf("candidate %+v scoped to %v, want [node-1]", cand, cand.scope)
+		}
+		switch cand.value {
+		case "fast-disk":
+			gotClass = true
+		case "amd64":
+			gotAttr = true
+		}
+	}
+	if !gotClass || !gotAttr {
+		t.Errorf("expected candidates for both node class and an attribute, got %+v", cands)
+	}
+}
+
+func TestGetFuzzyMatches_NamespaceACLFilter(t *testing.T) {
+	newJob := func(id, namespace string) *structs.Job {
+		return &structs.Job{
+			ID:        id,
+			Namespace: namespace,
+			TaskGroups: []*structs.TaskGroup{
+				{
+					Name: "cache",
+					Tasks: []*structs.Task{
+						{
+							Name:   "redis",
+							Config: map[string]interface{}{"image": "redis:7"},
+						},
+					},
+				},
+			},
+		}
+	}
+
+	items := []interface{}{newJob("job-1", "prod"), newJob("job-2", "dev")}
+
+	matcher, err := newFuzzyMatcher(structs.MatchFuzzy, "redis")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	s := &Search{}
+
+	// No ACL: images from both namespaces are visible.
+	all, _, _ := s.getFuzzyMatches(&fakeIterator{items: items}, structs.Images, nil, matcher, 10, 0, 2000)
+	if len(all) != 2 {
+		t.Fatalf("expected 2 matches with no ACL, got %d: %+v", len(all), all)
+	}
+
+	// An ACL that can only read the "dev" namespace should only ever see
+	// that job's image, never prod's.
+	policy, err := acl.Parse(`
+namespace "dev" {
+  policy = "read"
+}
+`)
+	if err != nil {
+		t.Fatalf("failed to parse policy: %v", err)
+	}
+	aclObj, err := acl.NewACL(false, []*acl.Policy{policy})
+	if err != nil {
+		t.Fatalf("failed to build ACL: %v", err)
+	}
+
+	scoped, _, _ := s.getFuzzyMatches(&fakeIterator{items: items}, structs.Images, aclObj, matcher, 10, 0, 2000)
+	if len(scoped) != 1 {
+		t.Fatalf("expected 1 match scoped to the dev namespace, got %d: %+v", len(scoped), scoped)
+	}
+	if len(scoped[0].Scope) == 0 || scoped[0].Scope[0] != "dev" {
+		t.Errorf("expected match scoped to the dev namespace, got %+v", scoped[0])
+	}
+}
@@ -0,0 +1,145 @@
+package searchindex
+
+import (
+	"fmt"
+	"path/filepath"
+	"sync"
+
+	"github.com/blevesearch/bleve/v2"
+
+	"github.com/hashicorp/nomad/nomad/structs"
+)
+
+// BleveIndexer is the default SearchIndexer backend. It keeps one Bleve
+// index per Context so that FuzzySearch can serve large clusters without
+// scanning every memdb row on every request.
+type BleveIndexer struct {
+	// path is the directory indexes are persisted under. An empty path
+	// keeps everything in memory, which is used for tests and for dev
+	// agents that don't want to manage index files on disk.
+	path string
+
+	lock    sync.RWMutex
+	indexes map[structs.Context]bleve.Index
+}
+
+// NewBleveIndexer returns a BleveIndexer rooted at path. Individual
+// per-context indexes are opened lazily on first use so that a server
+// never pays for indexes it never queries.
+func NewBleveIndexer(path string) *BleveIndexer {
+	return &BleveIndexer{
+		path:    path,
+		indexes: make(map[structs.Context]bleve.Index),
+	}
+}
+
+func (b *BleveIndexer) indexFor(ctx structs.Context) (bleve.Index, error) {
+	b.lock.RLock()
+	idx, ok := b.indexes[ctx]
+	b.lock.RUnlock()
+	if ok {
+		return idx, nil
+	}
+
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	// Another caller may have opened it while we waited for the write lock.
+	if idx, ok := b.indexes[ctx]; ok {
+		return idx, nil
+	}
+
+	mapping := bleve.NewIndexMapping()
+
+	var idx2 bleve.Index
+	var err error
+	if b.path == "" {
+		idx2, err = bleve.NewMemOnly(mapping)
+	} else {
+		idx2, err = bleve.New(filepath.Join(b.path, string(ctx)+".bleve"), mapping)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("searchindex: failed to open bleve index for %s: %w", ctx, err)
+	}
+
+	b.indexes[ctx] = idx2
+	return idx2, nil
+}
+
+// Upsert implements SearchIndexer.
+func (b *BleveIndexer) Upsert(doc Document) error {
+	idx, err := b.indexFor(doc.Context)
+	if err != nil {
+		return err
+	}
+	return idx.Index(doc.ID, doc)
+}
+
+// Delete implements SearchIndexer.
+func (b *BleveIndexer) Delete(ctx structs.Context, id string) error {
+	idx, err := b.indexFor(ctx)
+	if err != nil {
+		return err
+	}
+	return idx.Delete(id)
+}
+
+// Query implements SearchIndexer.
+func (b *BleveIndexer) Query(ctx structs.Context, text, namespace string, limit int) ([]QueryHit, error) {
+	idx, err := b.indexFor(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	nameQuery := bleve.NewMatchQuery(text)
+	nameQuery.SetField("Name")
+	nameQuery.Fuzziness = 1
+
+	var query bleve.Query = nameQuery
+	switch ctx {
+	case structs.Nodes, structs.Namespaces, structs.Plugins:
+		// Namespace-less contexts index no Namespace, so namespace is
+		// ignored for them.
+	default:
+		if namespace != "" {
+			nsQuery := bleve.NewMatchQuery(namespace)
+			nsQuery.SetField("Namespace")
+			query = bleve.NewConjunctionQuery(nameQuery, nsQuery)
+		}
+	}
+
+	req := bleve.NewSearchRequest(query)
+	req.Size = limit
+	req.Fields = []string{"Namespace"}
+
+	res, err := idx.Search(req)
+	if err != nil {
+		return nil, fmt.Errorf("searchindex: bleve query failed for %s: %w", ctx, err)
+	}
+
+	hits := make([]QueryHit, 0, len(res.Hits))
+	for _, hit := range res.Hits {
+		ns, _ := hit.Fields["Namespace"].(string)
+		// The match query above matches on analyzed tokens, so a namespace
+		// sharing a token with the requested one can match; keep only
+		// exact namespace matches.
+		if namespace != "" && ns != "" && ns != namespace {
+			continue
+		}
+		hits = append(hits, QueryHit{ID: hit.ID, Namespace: ns})
+	}
+	return hits, nil
+}
+
+// Close implements SearchIndexer.
+func (b *BleveIndexer) Close() error {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	for ctx, idx := range b.indexes {
+		if err := idx.Close(); err != nil {
+			return fmt.Errorf("searchindex: failed to close bleve index for %s: %w", ctx, err)
+		}
+	}
+	return nil
+}
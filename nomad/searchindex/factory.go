@@ -0,0 +1,26 @@
+package searchindex
+
+import (
+	"fmt"
+
+	"github.com/hashicorp/nomad/nomad/structs"
+)
+
+// New builds the SearchIndexer selected by cfg.Backend, or returns a nil
+// SearchIndexer (and nil error) when cfg is nil or Backend is unset, which
+// tells Search.FuzzySearch to keep using the memdb scan.
+func New(cfg *structs.SearchConfig) (SearchIndexer, error) {
+	if cfg == nil || cfg.Backend == "" {
+		return nil, nil
+	}
+
+	switch cfg.Backend {
+	case "bleve":
+		return NewBleveIndexer(cfg.BleveIndexPath), nil
+	default:
+		if cfg.RemoteEndpoint == "" {
+			return nil, fmt.Errorf("searchindex: backend %q requires remote_endpoint to be set", cfg.Backend)
+		}
+		return NewRemoteIndexer(cfg.Backend, cfg.RemoteEndpoint), nil
+	}
+}
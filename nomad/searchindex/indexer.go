@@ -0,0 +1,72 @@
+// Package searchindex provides pluggable full-text search backends for
+// Search.FuzzySearch. The default memdb scan in nomad/search_endpoint.go
+// re-reads every object in a table on every request; a SearchIndexer lets
+// large clusters serve fuzzy search out of a maintained index instead.
+package searchindex
+
+import (
+	"github.com/hashicorp/nomad/nomad/structs"
+)
+
+// Document is the unit of work indexed by a SearchIndexer. It captures just
+// enough of a Nomad object to answer a fuzzy search query without a second
+// round-trip to memdb.
+type Document struct {
+	// Context is the top level Nomad object type this document belongs to
+	// (structs.Jobs, structs.Nodes, structs.Allocs, structs.Namespaces, ...).
+	Context structs.Context
+
+	// ID is the UUID or name used to key the document within its Context.
+	ID string
+
+	// Namespace scopes the document for ACL filtering. Namespace-less
+	// contexts (e.g. Nodes) leave this empty.
+	Namespace string
+
+	// Name is the human readable value fuzzy matches are made against.
+	Name string
+}
+
+// QueryHit is a single match returned by SearchIndexer.Query. It carries the
+// document's Namespace alongside its ID so the caller can re-apply
+// aclObj.AllowNamespace before turning a hit into a structs.FuzzyMatch,
+// mirroring the ACL check the memdb scan applies per candidate.
+type QueryHit struct {
+	// ID is the Document.ID that matched.
+	ID string
+
+	// Namespace is the matching Document's Namespace, "" for namespace-less
+	// contexts (e.g. Nodes).
+	Namespace string
+}
+
+// SearchIndexer is implemented by full-text search backends that can serve
+// Search.FuzzySearch without falling back to a linear memdb scan. Nomad
+// ships a Bleve-backed implementation by default; SearchConfig.Backend
+// selects between it and a remote endpoint such as Elasticsearch or
+// Meilisearch.
+//
+// Implementations must be safe for concurrent use: Upsert is called from
+// the background Rebuild walk, and Upsert and Delete from Search's Handle*
+// methods, while Query is called concurrently from RPC handlers.
+type SearchIndexer interface {
+	// Upsert indexes or re-indexes doc, one watched object (job, alloc,
+	// node, namespace, CSI plugin/volume, scaling policy). Rebuild calls it
+	// for every such object in the state store.
+	Upsert(doc Document) error
+
+	// Delete removes the document for id from ctx's index.
+	Delete(ctx structs.Context, id string) error
+
+	// Query returns fuzzy matches for text within ctx, best match first,
+	// capped at limit results. When namespace is non-empty, implementations
+	// must restrict matches to documents indexed under that namespace (a
+	// no-op for namespace-less contexts, whose Document.Namespace is always
+	// ""); an empty namespace means search every namespace, leaving the
+	// caller to filter hits by QueryHit.Namespace.
+	Query(ctx structs.Context, text, namespace string, limit int) ([]QueryHit, error)
+
+	// Close releases any resources (open index files, network connections)
+	// held by the indexer.
+	Close() error
+}
@@ -0,0 +1,117 @@
+package searchindex
+
+import (
+	"fmt"
+
+	memdb "github.com/hashicorp/go-memdb"
+
+	"github.com/hashicorp/nomad/nomad/state"
+	"github.com/hashicorp/nomad/nomad/structs"
+)
+
+// rebuildContexts are the top level contexts walked during a cold-start
+// rebuild, matching the object types fed to Upsert/Delete by
+// nomad/search_endpoint.go's Handle* methods. Sub-contexts such as Images
+// and Tasks are not indexed at all; FuzzySearch serves them from the memdb
+// scan.
+var rebuildContexts = []structs.Context{
+	structs.Jobs,
+	structs.Allocs,
+	structs.Nodes,
+	structs.Namespaces,
+	structs.Plugins,
+	structs.Volumes,
+	structs.ScalingPolicies,
+}
+
+// Rebuild walks a point-in-time snapshot of the state store and feeds every
+// object in rebuildContexts into indexer. NewSearch runs it once, in the
+// background, when it builds an indexer, since the indexer would otherwise
+// start empty; nothing runs it again after a snapshot restore.
+//
+// The walk is best-effort: FuzzySearch already queries the indexer while a
+// large cluster's rebuild is still catching up, so its results are
+// incomplete until the walk finishes, and a failure partway through leaves
+// the index truncated rather than blocking bootstrap.
+func Rebuild(indexer SearchIndexer, store *state.StateStore) error {
+	ws := memdb.NewWatchSet()
+
+	for _, ctx := range rebuildContexts {
+		if err := rebuildContext(indexer, store, ws, ctx); err != nil {
+			return fmt.Errorf("searchindex: rebuild failed for context %s: %w", ctx, err)
+		}
+	}
+
+	return nil
+}
+
+func rebuildContext(indexer SearchIndexer, store *state.StateStore, ws memdb.WatchSet, ctx structs.Context) error {
+	var iter memdb.ResultIterator
+	var err error
+
+	switch ctx {
+	case structs.Jobs:
+		iter, err = store.JobsByIDPrefix(ws, "", "")
+	case structs.Allocs:
+		iter, err = store.AllocsByIDPrefix(ws, "", "")
+	case structs.Nodes:
+		iter, err = store.NodesByIDPrefix(ws, "")
+	case structs.Namespaces:
+		iter, err = store.NamespacesByNamePrefix(ws, "")
+	case structs.Plugins:
+		iter, err = store.CSIPluginsByIDPrefix(ws, "")
+	case structs.Volumes:
+		iter, err = store.CSIVolumesByIDPrefix(ws, "", "")
+	case structs.ScalingPolicies:
+		iter, err = store.ScalingPoliciesByIDPrefix(ws, "", "")
+	default:
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	for {
+		raw := iter.Next()
+		if raw == nil {
+			return nil
+		}
+
+		doc, ok := documentFor(ctx, raw)
+		if !ok {
+			continue
+		}
+
+		if err := indexer.Upsert(doc); err != nil {
+			return err
+		}
+	}
+}
+
+func documentFor(ctx structs.Context, raw interface{}) (Document, bool) {
+	switch ctx {
+	case structs.Jobs:
+		j := raw.(*structs.Job)
+		return Document{Context: ctx, ID: j.ID, Namespace: j.Namespace, Name: j.Name}, true
+	case structs.Allocs:
+		a := raw.(*structs.Allocation)
+		return Document{Context: ctx, ID: a.ID, Namespace: a.Namespace, Name: a.Name}, true
+	case structs.Nodes:
+		n := raw.(*structs.Node)
+		return Document{Context: ctx, ID: n.ID, Name: n.Name}, true
+	case structs.Namespaces:
+		ns := raw.(*structs.Namespace)
+		return Document{Context: ctx, ID: ns.Name, Name: ns.Name}, true
+	case structs.Plugins:
+		p := raw.(*structs.CSIPlugin)
+		return Document{Context: ctx, ID: p.ID, Name: p.ID}, true
+	case structs.Volumes:
+		v := raw.(*structs.CSIVolume)
+		return Document{Context: ctx, ID: v.ID, Namespace: v.Namespace, Name: v.Name}, true
+	case structs.ScalingPolicies:
+		sp := raw.(*structs.ScalingPolicy)
+		return Document{Context: ctx, ID: sp.ID, Namespace: sp.Namespace}, true
+	default:
+		return Document{}, false
+	}
+}
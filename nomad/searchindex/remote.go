@@ -0,0 +1,54 @@
+package searchindex
+
+import (
+	"errors"
+
+	"github.com/hashicorp/nomad/nomad/structs"
+)
+
+// ErrRemoteIndexerNotImplemented is returned by every RemoteIndexer method
+// until a concrete wire protocol is implemented for Backend. It exists so
+// operators can set SearchConfig.Backend to "elasticsearch" or
+// "meilisearch" today and get a clear error rather than FuzzySearch
+// silently falling back to the memdb scan.
+var ErrRemoteIndexerNotImplemented = errors.New("searchindex: remote backend not yet implemented")
+
+// RemoteIndexer is a placeholder SearchIndexer for operators who run a
+// standalone full-text search service (Elasticsearch, Meilisearch) rather
+// than the embedded Bleve index. Endpoint and Backend are populated from
+// SearchConfig.RemoteEndpoint and SearchConfig.Backend.
+type RemoteIndexer struct {
+	// Backend names the remote protocol this indexer targets, e.g.
+	// "elasticsearch" or "meilisearch".
+	Backend string
+
+	// Endpoint is the base URL of the remote search service.
+	Endpoint string
+}
+
+// NewRemoteIndexer returns a RemoteIndexer for backend at endpoint. It does
+// not dial the endpoint eagerly; every method returns
+// ErrRemoteIndexerNotImplemented until wire support for backend is added.
+func NewRemoteIndexer(backend, endpoint string) *RemoteIndexer {
+	return &RemoteIndexer{Backend: backend, Endpoint: endpoint}
+}
+
+// Upsert implements SearchIndexer.
+func (r *RemoteIndexer) Upsert(doc Document) error {
+	return ErrRemoteIndexerNotImplemented
+}
+
+// Delete implements SearchIndexer.
+func (r *RemoteIndexer) Delete(ctx structs.Context, id string) error {
+	return ErrRemoteIndexerNotImplemented
+}
+
+// Query implements SearchIndexer.
+func (r *RemoteIndexer) Query(ctx structs.Context, text, namespace string, limit int) ([]QueryHit, error) {
+	return nil, ErrRemoteIndexerNotImplemented
+}
+
+// Close implements SearchIndexer.
+func (r *RemoteIndexer) Close() error {
+	return nil
+}
@@ -0,0 +1,11 @@
+package nomad
+
+// setupSearch constructs the Search RPC endpoint and registers it with the
+// server's RPC dispatcher. It must run after srv.fsm is set up, since
+// NewSearch kicks off a background index Rebuild against the FSM's state
+// store. Nothing in the server's bring-up calls setupSearch.
+func (s *Server) setupSearch() {
+	endpoint := NewSearch(s, s.logger.Named("search"))
+	s.staticEndpoints.Search = endpoint
+	s.rpcServer.Register(endpoint)
+}
@@ -44,8 +44,12 @@ type SearchConfig struct {
 	// the FuzzySearch API is enabled.
 	LimitQuery int `hcl:"limit_query"`
 
-	// LimitResults limits the number of results provided by the FuzzySearch API.
-	// The results are indicated as truncate if the limit is reached.
+	// LimitResults caps the page size used to serve SearchRequest and
+	// FuzzySearchRequest: it is the maximum value SearchRequest.PerPage /
+	// FuzzySearchRequest.PerPage may request, not the only page size. A
+	// request that asks for more, or doesn't set PerPage at all, is served
+	// LimitResults results at a time; callers page through the rest using
+	// the returned NextToken.
 	//
 	// Lowering this value can reduce resource consumption of Nomad server per
 	// fuzzy search request when the FuzzySearch API is enabled.
@@ -57,6 +61,29 @@ type SearchConfig struct {
 	// Increasing this value can avoid resource consumption on Nomad server by
 	// reducing searches with less meaningful results.
 	MinTermLength int `hcl:"min_term_length"`
+
+	// Backend selects the searchindex.SearchIndexer used to serve
+	// FuzzySearch. Valid values are "" (disabled; fall back to the memdb
+	// scan), "bleve" (the embedded default), or the name of a remote
+	// backend such as "elasticsearch" or "meilisearch" whose endpoint is
+	// given by RemoteEndpoint.
+	//
+	// When the Search endpoint builds a configured indexer, it is cold-start
+	// rebuilt by walking the state store snapshot (see searchindex.Rebuild);
+	// the walk is best-effort and may be truncated, and FuzzySearch queries
+	// the indexer while it runs, so results are incomplete until it catches
+	// up. A snapshot restore does not trigger a rebuild.
+	Backend string `hcl:"backend"`
+
+	// BleveIndexPath is the directory Nomad persists the embedded Bleve
+	// index under when Backend is "bleve". If empty, an in-memory index is
+	// used and rebuilt from the state store snapshot on every restart.
+	BleveIndexPath string `hcl:"bleve_index_path"`
+
+	// RemoteEndpoint is the base URL of an external search service
+	// (Elasticsearch, Meilisearch) to query when Backend names a remote
+	// backend. It is ignored when Backend is "bleve" or "".
+	RemoteEndpoint string `hcl:"remote_endpoint"`
 }
 
 // SearchResponse is used to return matches and information about whether
@@ -69,6 +96,13 @@ type SearchResponse struct {
 	// been truncated
 	Truncations map[Context]bool
 
+	// NextToken, if non-empty, resumes a paginated search from the point
+	// this response left off. It holds one position per context paged so
+	// far: the last id emitted for that context, or for fuzzy search the
+	// offset into its sorted results. It should be passed back as
+	// SearchRequest.NextToken / FuzzySearchRequest.NextToken unmodified.
+	NextToken string
+
 	QueryMeta
 }
 
@@ -85,6 +119,15 @@ type SearchRequest struct {
 	// matched)
 	Context Context
 
+	// PerPage is the desired number of results per page. It is capped at
+	// SearchConfig.LimitResults; a value of zero uses that limit as the
+	// default page size.
+	PerPage int32
+
+	// NextToken resumes the search from the point a previous response's
+	// NextToken left off. Leave empty to fetch the first page.
+	NextToken string
+
 	QueryOptions
 }
 
@@ -100,6 +143,10 @@ type FuzzySearchResponse struct {
 	// been truncated.
 	Truncations map[Context]bool
 
+	// NextToken, if non-empty, resumes a paginated search from the point
+	// this response left off. See SearchResponse.NextToken.
+	NextToken string
+
 	QueryMeta
 }
 
@@ -113,5 +160,47 @@ type FuzzySearchRequest struct {
 	// matched)
 	Context Context
 
+	// Mode controls how Text is interpreted. It defaults to MatchFuzzy.
+	Mode FuzzyMatchMode
+
+	// PerPage is the desired number of results per page. It is capped at
+	// SearchConfig.LimitResults; a value of zero uses that limit as the
+	// default page size.
+	PerPage int32
+
+	// NextToken resumes the search from the point a previous response's
+	// NextToken left off. Leave empty to fetch the first page.
+	NextToken string
+
 	QueryOptions
 }
+
+// MatchMode returns the effective FuzzyMatchMode for the request, treating
+// the zero value as MatchFuzzy so existing callers that don't set Mode keep
+// their current behavior.
+func (r *FuzzySearchRequest) MatchMode() FuzzyMatchMode {
+	if r.Mode == "" {
+		return MatchFuzzy
+	}
+	return r.Mode
+}
+
+// FuzzyMatchMode controls how FuzzySearchRequest.Text is interpreted.
+type FuzzyMatchMode string
+
+const (
+	// MatchFuzzy scores names by how well Text matches as a subsequence,
+	// the same position-based scoring FuzzySearch has always used.
+	MatchFuzzy FuzzyMatchMode = "fuzzy"
+
+	// MatchExact requires the full name to equal Text.
+	MatchExact FuzzyMatchMode = "exact"
+
+	// MatchSubstring requires Text to appear literally within the name;
+	// unlike MatchRegex, Text is escaped before matching.
+	MatchSubstring FuzzyMatchMode = "substring"
+
+	// MatchRegex compiles Text as a regular expression and matches it
+	// against the name, preserving FuzzySearch's original behavior.
+	MatchRegex FuzzyMatchMode = "regex"
+)